@@ -0,0 +1,110 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/congestion"
+	"github.com/lucas-clemente/quic-go/protocol"
+)
+
+// deliveryWindowRounds bounds how many ACKs the pacer's bandwidth filter
+// remembers, mirroring congestion.bandwidthWindowSize.
+const deliveryWindowRounds = 10
+
+// pacer spaces packets out over a round trip instead of letting
+// CongestionAllowsSending wave the whole congestion window through
+// back-to-back right after an ACK arrives - exactly the bursty behavior BBR
+// and modern TCP stacks avoid by pacing. It tracks delivery rate itself
+// (deliveredBytes/deliveredTime bookkeeping per sent packet, same technique
+// BBR uses), independent of whatever the active congestion.SendAlgorithm
+// does internally, so any algorithm can benefit from it.
+type pacer struct {
+	deliveredBytes protocol.ByteCount
+	deliveredTime  time.Time
+	round          uint64
+
+	// sendRecord snapshots (deliveredBytes, deliveredTime) at the moment a
+	// packet was sent, so that when it's later acked we can compute the
+	// delivery rate over the interval it was in flight.
+	sendRecord map[protocol.PacketNumber]pacerSendRecord
+
+	bandwidthFilter *congestion.WindowedMaxFilter
+}
+
+type pacerSendRecord struct {
+	deliveredBytes protocol.ByteCount
+	deliveredTime  time.Time
+}
+
+func newPacer() pacer {
+	return pacer{
+		sendRecord:      make(map[protocol.PacketNumber]pacerSendRecord),
+		bandwidthFilter: congestion.NewWindowedMaxFilter(deliveryWindowRounds),
+	}
+}
+
+// OnPacketSent records the delivery-rate bookkeeping state as of the moment
+// packetNumber was sent.
+func (p *pacer) OnPacketSent(packetNumber protocol.PacketNumber, now time.Time) {
+	deliveredTime := p.deliveredTime
+	if deliveredTime.IsZero() {
+		deliveredTime = now
+	}
+	p.sendRecord[packetNumber] = pacerSendRecord{
+		deliveredBytes: p.deliveredBytes,
+		deliveredTime:  deliveredTime,
+	}
+}
+
+// OnPacketAcked folds a newly-acked packet into the delivery-rate estimate:
+// bytes delivered over the interval since it was sent, fed into a windowed
+// max filter so a single lucky round trip doesn't dominate the estimate.
+func (p *pacer) OnPacketAcked(packetNumber protocol.PacketNumber, length protocol.ByteCount, now time.Time) {
+	p.deliveredBytes += length
+	p.deliveredTime = now
+	p.round++
+
+	record, ok := p.sendRecord[packetNumber]
+	delete(p.sendRecord, packetNumber)
+	if !ok {
+		return
+	}
+
+	elapsed := now.Sub(record.deliveredTime)
+	sample := congestion.BandwidthFromDelta(uint64(p.deliveredBytes-record.deliveredBytes), elapsed)
+	p.bandwidthFilter.Update(sample, p.round)
+}
+
+// OnPacketLost drops the bookkeeping for a packet that will never be acked
+// under its original packet number, so sendRecord doesn't grow unbounded.
+func (p *pacer) OnPacketLost(packetNumber protocol.PacketNumber) {
+	delete(p.sendRecord, packetNumber)
+}
+
+// DeliveryRate returns the pacer's own windowed estimate of the connection's
+// delivery rate.
+func (p *pacer) DeliveryRate() congestion.Bandwidth {
+	return p.bandwidthFilter.Max()
+}
+
+// TimeUntilSend returns how long to wait before the pacing rate (cwnd/SRTT,
+// scaled by the congestion controller's current pacing gain) allows sending
+// another datagram-sized packet. A zero duration means sending now is fine.
+func (p *pacer) TimeUntilSend(now, lastSentPacketTime time.Time, cwnd protocol.ByteCount, srtt time.Duration, gain float32) time.Duration {
+	if srtt <= 0 || cwnd <= 0 || gain <= 0 || lastSentPacketTime.IsZero() {
+		return 0
+	}
+
+	pacingRate := congestion.BandwidthFromDelta(uint64(cwnd), srtt)
+	pacingRate = congestion.Bandwidth(float32(pacingRate) * gain)
+	if pacingRate == 0 {
+		return 0
+	}
+
+	interval := time.Duration(uint64(congestion.DefaultMaxDatagramSize) * uint64(time.Second) / uint64(pacingRate))
+	earliestSend := lastSentPacketTime.Add(interval)
+	if !now.Before(earliestSend) {
+		return 0
+	}
+	return earliestSend.Sub(now)
+}
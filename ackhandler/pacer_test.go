@@ -0,0 +1,64 @@
+package ackhandler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/congestion"
+)
+
+func TestPacerDeliveryRateReflectsAckedBytes(t *testing.T) {
+	p := newPacer()
+	now := time.Now()
+
+	p.OnPacketSent(1, now)
+	now = now.Add(100 * time.Millisecond)
+	p.OnPacketAcked(1, 10*congestion.DefaultMaxDatagramSize, now)
+
+	if got := p.DeliveryRate(); got == 0 {
+		t.Fatalf("DeliveryRate() = 0 after an acked packet, want a positive estimate")
+	}
+}
+
+func TestPacerOnPacketLostDropsBookkeeping(t *testing.T) {
+	p := newPacer()
+	now := time.Now()
+
+	p.OnPacketSent(1, now)
+	p.OnPacketLost(1)
+
+	// Acking a packet number whose send record was dropped as lost must not
+	// panic or fold bogus bytes into the delivery-rate estimate.
+	p.OnPacketAcked(1, congestion.DefaultMaxDatagramSize, now.Add(time.Millisecond))
+	if got := p.DeliveryRate(); got != 0 {
+		t.Fatalf("DeliveryRate() = %d, want 0 since the sent packet's bookkeeping was dropped as lost", got)
+	}
+}
+
+func TestPacerTimeUntilSendPacesBelowCwndOverSRTT(t *testing.T) {
+	p := newPacer()
+	now := time.Now()
+	lastSent := now
+
+	// cwnd/srtt pacing rate implies one max-datagram-sized packet must be
+	// spaced out over srtt/(cwnd/maxDatagramSize); with cwnd exactly one
+	// datagram and srtt of 100ms, that's a 100ms gap.
+	wait := p.TimeUntilSend(now, lastSent, congestion.DefaultMaxDatagramSize, 100*time.Millisecond, 1)
+	if wait <= 0 {
+		t.Fatalf("TimeUntilSend() = %v immediately after a send, want a positive wait", wait)
+	}
+
+	later := lastSent.Add(wait)
+	if got := p.TimeUntilSend(later, lastSent, congestion.DefaultMaxDatagramSize, 100*time.Millisecond, 1); got != 0 {
+		t.Fatalf("TimeUntilSend() at the computed deadline = %v, want 0", got)
+	}
+}
+
+func TestPacerTimeUntilSendZeroWithoutRTTSample(t *testing.T) {
+	p := newPacer()
+	now := time.Now()
+
+	if got := p.TimeUntilSend(now, time.Time{}, congestion.DefaultMaxDatagramSize, 0, 1); got != 0 {
+		t.Fatalf("TimeUntilSend() with no prior send/RTT sample = %v, want 0 (send immediately)", got)
+	}
+}
@@ -0,0 +1,75 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/protocol"
+)
+
+// ProbeSnapshot is an immutable snapshot of a sentPacketHandler's state,
+// handed to a Probe after every SentPacket and ReceivedAck call. It exists
+// so that connection-level tracing, qlog-style exporters, and
+// congestion-control unit tests can observe bytes-in-flight, cwnd, and the
+// ack/loss vectors on a live connection without copying the handler.
+type ProbeSnapshot struct {
+	LastSentPacketNumber protocol.PacketNumber
+	LargestAcked         protocol.PacketNumber
+	LargestInOrderAcked  protocol.PacketNumber
+
+	BytesInFlight    protocol.ByteCount
+	CongestionWindow protocol.ByteCount
+
+	SmoothedRTT time.Duration
+	RTTVar      time.Duration
+	LatestRTT   time.Duration
+
+	RetransmissionQueueLen int
+	PacketHistoryLen       int
+
+	// AckedThisEvent and LostThisEvent are only non-zero on the call that
+	// follows a ReceivedAck; SentPacket always reports them as 0.
+	AckedThisEvent int
+	LostThisEvent  int
+}
+
+// Probe is called with a ProbeSnapshot after every SentPacket and
+// ReceivedAck call.
+type Probe func(ProbeSnapshot)
+
+// WithProbe registers a Probe on the handler. Config.Probe (or an
+// equivalent per-connection hook) is expected to be threaded through to
+// here from session setup, but that Config field and the session's call
+// site live outside this package and are not added here - until that
+// wiring lands, this option has no caller outside the ackhandler package
+// itself.
+func WithProbe(p Probe) SentPacketHandlerOption {
+	return func(h *sentPacketHandler) {
+		h.probe = p
+	}
+}
+
+// fireProbe builds a ProbeSnapshot of the handler's current state and hands
+// it to the registered Probe, if any. acked/lost are the numbers of packets
+// the call that just finished newly acked and declared lost; lost counts
+// every packet detectLosses declared lost, including those not forwarded to
+// the congestion controller because an open recovery phase already covers
+// them. Both are 0 when called from SentPacket.
+func (h *sentPacketHandler) fireProbe(acked, lost int) {
+	if h.probe == nil {
+		return
+	}
+	h.probe(ProbeSnapshot{
+		LastSentPacketNumber:   h.lastSentPacketNumber,
+		LargestAcked:           h.LargestAcked,
+		LargestInOrderAcked:    h.LargestInOrderAcked,
+		BytesInFlight:          h.BytesInFlight(),
+		CongestionWindow:       h.congestion.GetCongestionWindow(),
+		SmoothedRTT:            h.rttStats.SmoothedRTT(),
+		RTTVar:                 h.rttStats.MeanDeviation(),
+		LatestRTT:              h.rttStats.LatestRTT(),
+		RetransmissionQueueLen: len(h.retransmissionQueue),
+		PacketHistoryLen:       h.packetHistory.Len(),
+		AckedThisEvent:         acked,
+		LostThisEvent:          lost,
+	})
+}
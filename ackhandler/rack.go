@@ -0,0 +1,70 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/congestion"
+	"github.com/lucas-clemente/quic-go/protocol"
+)
+
+// rackState implements RACK (Recent ACKnowledgment, draft-ietf-tcpm-rack):
+// a packet is declared lost once enough time has passed since a packet sent
+// strictly later was delivered, rather than by counting SACKed ranges. This
+// catches losses that dupack-style counting misses when only a handful of
+// packets are in flight, while still tolerating genuine reordering via the
+// reoWnd grace period.
+type rackState struct {
+	// xmitTime/endSeq are the send time and packet number of the most
+	// recently sent packet among those delivered so far.
+	xmitTime time.Time
+	endSeq   protocol.PacketNumber
+
+	// reoWnd is the reordering allowance: a packet sent before xmitTime isn't
+	// declared lost until this long after its own send time. It starts at
+	// SRTT/4 and grows to SRTT once reordering (not loss) has actually been
+	// observed on this connection.
+	reoWnd      time.Duration
+	reorderSeen bool
+}
+
+// OnPacketAcked updates the RACK state with a packet the peer just
+// acknowledged. highRxt is the handler's current HighRxt, the highest packet
+// number already declared lost: if the acked packet is at or below it, a
+// packet older than one declared lost was still delivered, so the path
+// reorders packets, and reoWnd is grown accordingly.
+func (r *rackState) OnPacketAcked(packetNumber protocol.PacketNumber, sendTime time.Time, highRxt protocol.PacketNumber, rttStats *congestion.RTTStats) {
+	if r.xmitTime.IsZero() || sendTime.After(r.xmitTime) {
+		r.xmitTime = sendTime
+		r.endSeq = packetNumber
+	}
+	if highRxt != 0 && packetNumber <= highRxt {
+		r.reorderSeen = true
+	}
+
+	srtt := rttStats.SmoothedRTT()
+	if r.reorderSeen {
+		r.reoWnd = srtt
+	} else {
+		r.reoWnd = srtt / 4
+	}
+}
+
+// IsLost reports whether a packet sent at sendTime should be declared lost:
+// it was sent strictly before the most recently delivered packet, and the
+// reordering window has since elapsed without it showing up.
+func (r *rackState) IsLost(sendTime time.Time, now time.Time) bool {
+	if r.xmitTime.IsZero() || !sendTime.Before(r.xmitTime) {
+		return false
+	}
+	return now.Sub(sendTime) > r.reoWnd
+}
+
+// NextLossCheck returns the time at which IsLost would first become true for
+// a packet sent at sendTime, so that a reoWndTimer can be armed for it
+// instead of waiting on the next incoming ACK.
+func (r *rackState) NextLossCheck(sendTime time.Time) time.Time {
+	if r.reoWnd == 0 || r.xmitTime.IsZero() || !sendTime.Before(r.xmitTime) {
+		return time.Time{}
+	}
+	return sendTime.Add(r.reoWnd)
+}
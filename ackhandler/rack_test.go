@@ -0,0 +1,80 @@
+package ackhandler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/congestion"
+)
+
+func TestRackIsLostRequiresReoWndElapsed(t *testing.T) {
+	var r rackState
+	rttStats := &congestion.RTTStats{}
+	rttStats.UpdateRTT(100*time.Millisecond, 0, time.Now())
+
+	sendTime := time.Now()
+	r.OnPacketAcked(10, sendTime.Add(50*time.Millisecond), 0, rttStats)
+
+	if r.IsLost(sendTime, sendTime.Add(10*time.Millisecond)) {
+		t.Fatalf("IsLost should be false before reoWnd has elapsed")
+	}
+	if !r.IsLost(sendTime, sendTime.Add(time.Second)) {
+		t.Fatalf("IsLost should be true once reoWnd has elapsed for a packet sent before xmitTime")
+	}
+}
+
+func TestRackIsLostFalseForPacketsNotBeforeXmitTime(t *testing.T) {
+	var r rackState
+	rttStats := &congestion.RTTStats{}
+	rttStats.UpdateRTT(100*time.Millisecond, 0, time.Now())
+
+	sendTime := time.Now()
+	r.OnPacketAcked(10, sendTime, 0, rttStats)
+
+	// sendTime itself is not strictly before r.xmitTime, so it can never be
+	// declared lost by RACK regardless of how much time passes.
+	if r.IsLost(sendTime, sendTime.Add(time.Hour)) {
+		t.Fatalf("IsLost should be false for a packet sent at or after xmitTime")
+	}
+}
+
+func TestRackReorderingGrowsReoWndFromQuarterRTTToSRTT(t *testing.T) {
+	var r rackState
+	rttStats := &congestion.RTTStats{}
+	rttStats.UpdateRTT(100*time.Millisecond, 0, time.Now())
+	srtt := rttStats.SmoothedRTT()
+
+	sendTime := time.Now()
+	r.OnPacketAcked(10, sendTime.Add(time.Second), 0, rttStats)
+	if r.reoWnd != srtt/4 {
+		t.Fatalf("reoWnd before any reordering = %v, want SRTT/4 = %v", r.reoWnd, srtt/4)
+	}
+
+	// An ACK for a packet at or below HighRxt means a packet older than one
+	// already declared lost was delivered; that is reordering, not loss, so
+	// reoWnd should grow to the full SRTT.
+	r.OnPacketAcked(5, sendTime, 5, rttStats)
+	if r.reoWnd != srtt {
+		t.Fatalf("reoWnd after reordering = %v, want SRTT = %v", r.reoWnd, srtt)
+	}
+}
+
+func TestRackNextLossCheckMatchesIsLostBoundary(t *testing.T) {
+	var r rackState
+	rttStats := &congestion.RTTStats{}
+	rttStats.UpdateRTT(100*time.Millisecond, 0, time.Now())
+
+	sendTime := time.Now()
+	r.OnPacketAcked(10, sendTime.Add(50*time.Millisecond), 0, rttStats)
+
+	deadline := r.NextLossCheck(sendTime)
+	if deadline.IsZero() {
+		t.Fatalf("NextLossCheck returned zero time for a packet sent before xmitTime")
+	}
+	if r.IsLost(sendTime, deadline) {
+		t.Fatalf("IsLost should still be false exactly at the NextLossCheck deadline (strict >)")
+	}
+	if !r.IsLost(sendTime, deadline.Add(time.Nanosecond)) {
+		t.Fatalf("IsLost should be true just past the NextLossCheck deadline")
+	}
+}
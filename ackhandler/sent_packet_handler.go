@@ -43,26 +43,73 @@ type sentPacketHandler struct {
 
 	rttStats   *congestion.RTTStats
 	congestion congestion.SendAlgorithm
+
+	// HighRxt is the highest packet number retransmitted during the current
+	// loss recovery phase (RFC 6675).
+	HighRxt protocol.PacketNumber
+	// RescueRxt is the highest packet number retransmitted to keep the ACK
+	// clock going while no new data was available to send.
+	RescueRxt protocol.PacketNumber
+	// recoveryPoint is lastSentPacketNumber at the time the current loss
+	// recovery phase was entered. It is 0 when not in recovery. Losses below
+	// recoveryPoint don't start a new recovery phase or cut cwnd again; the
+	// phase ends once an ACK covers recoveryPoint.
+	recoveryPoint protocol.PacketNumber
+
+	rack rackState
+	// tlpSent is true once a Tail Loss Probe has been sent for the packet
+	// currently at the tail of lastSentPacketTime; it's cleared whenever new
+	// data is sent or a full RTO fires, so at most one TLP happens per gap.
+	tlpSent bool
+
+	probe Probe
+	pacer pacer
 }
 
-// NewSentPacketHandler creates a new sentPacketHandler
-func NewSentPacketHandler() SentPacketHandler {
-	rttStats := &congestion.RTTStats{}
+// SentPacketHandlerOption configures a sentPacketHandler created via
+// NewSentPacketHandler.
+type SentPacketHandlerOption func(*sentPacketHandler)
+
+// WithCongestionControl overrides the default CUBIC congestion controller.
+// This is the hook a Config.CongestionControl setting is meant to be wired
+// through: build the algorithm with congestion.NewSendAlgorithm (passing it
+// the same RTTStats the handler exposes) and inject it here, so CUBIC, Reno
+// and BBR can all be A/B'd on the same connection setup path. Config and the
+// session's handler construction call site live outside this package and
+// are not touched here - until that wiring lands, this option has no caller
+// outside the ackhandler/congestion packages themselves.
+func WithCongestionControl(cc congestion.SendAlgorithm) SentPacketHandlerOption {
+	return func(h *sentPacketHandler) {
+		h.congestion = cc
+	}
+}
 
-	congestion := congestion.NewCubicSender(
-		congestion.DefaultClock{},
-		rttStats,
-		false, /* don't use reno since chromium doesn't (why?) */
-		protocol.InitialCongestionWindow,
-		protocol.DefaultMaxCongestionWindow,
-	)
+// NewSentPacketHandler creates a new sentPacketHandler. Without any options,
+// it defaults to CUBIC.
+func NewSentPacketHandler(opts ...SentPacketHandlerOption) SentPacketHandler {
+	rttStats := &congestion.RTTStats{}
 
-	return &sentPacketHandler{
+	h := &sentPacketHandler{
 		packetHistory:      ackhandlerlegacy.NewPacketList(),
 		stopWaitingManager: stopWaitingManager{},
 		rttStats:           rttStats,
-		congestion:         congestion,
+		pacer:              newPacer(),
 	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	if h.congestion == nil {
+		h.congestion = congestion.NewCubicSender(
+			congestion.DefaultClock{},
+			rttStats,
+			protocol.InitialCongestionWindow,
+			protocol.DefaultMaxCongestionWindow,
+		)
+	}
+
+	return h
 }
 
 func (h *sentPacketHandler) ackPacket(packetElement *ackhandlerlegacy.PacketElement) *ackhandlerlegacy.Packet {
@@ -83,16 +130,83 @@ func (h *sentPacketHandler) ackPacket(packetElement *ackhandlerlegacy.PacketElem
 	return packet
 }
 
-func (h *sentPacketHandler) nackPacket(packetElement *ackhandlerlegacy.PacketElement) (*ackhandlerlegacy.Packet, error) {
-	packet := &packetElement.Value
+// inRecovery reports whether the sentPacketHandler is currently in an RFC
+// 6675 loss recovery phase.
+func (h *sentPacketHandler) inRecovery() bool {
+	return h.recoveryPoint != 0
+}
+
+// detectLosses declares a packet lost if either (a) RACK's time-ordered check
+// says a packet sent strictly before the most recently delivered one never
+// showed up within the reordering window (replacing RFC 6675-style dupack
+// counting), or (b) the packet is old enough that the pipe must have emptied
+// without it ever arriving. Every packet it declares lost is queued for
+// retransmission and HighRxt is advanced to match.
+func (h *sentPacketHandler) detectLosses(now time.Time) congestion.PacketVector {
+	var lostPackets congestion.PacketVector
 
-	packet.MissingReports++
+	rtt := h.rttStats.SmoothedRTT()
+	pipeEmptyDelay := rtt + utils.MaxDuration(rtt/4, protocol.MinRetransmissionTime/4)
 
-	if packet.MissingReports > protocol.RetransmissionThreshold {
-		h.queuePacketForRetransmission(packetElement)
-		return packet, nil
+	var el, elNext *ackhandlerlegacy.PacketElement
+	for el = h.packetHistory.Front(); el != nil; el = elNext {
+		elNext = el.Next()
+		packetNumber := el.Value.PacketNumber
+		if packetNumber > h.LargestAcked {
+			break
+		}
+
+		lost := h.rack.IsLost(el.Value.SendTime, now) ||
+			(rtt > 0 && now.Sub(el.Value.SendTime) > pipeEmptyDelay)
+		if !lost {
+			continue
+		}
+
+		packet := el.Value
+		if packetNumber > h.HighRxt {
+			h.HighRxt = packetNumber
+		}
+		lostPackets = append(lostPackets, congestion.PacketInfo{Number: packet.PacketNumber, Length: packet.Length})
+		h.queuePacketForRetransmission(el)
+	}
+
+	return lostPackets
+}
+
+// TimeOfNextLossDetectionTimeout returns the earliest time at which
+// detectLosses would newly declare an outstanding packet lost under RACK, so
+// the caller can arm a reoWndTimer instead of waiting on the next ACK to
+// re-check.
+func (h *sentPacketHandler) TimeOfNextLossDetectionTimeout() time.Time {
+	var earliest time.Time
+	for el := h.packetHistory.Front(); el != nil; el = el.Next() {
+		if el.Value.PacketNumber > h.LargestAcked {
+			break
+		}
+		t := h.rack.NextLossCheck(el.Value.SendTime)
+		if t.IsZero() {
+			continue
+		}
+		if earliest.IsZero() || t.Before(earliest) {
+			earliest = t
+		}
 	}
-	return nil, nil
+	return earliest
+}
+
+// MaybeQueueRescueRetransmission retransmits the highest outstanding packet
+// to keep the ACK clock ticking when there's no new data to send and a loss
+// recovery phase would otherwise stall. It fires at most once per phase.
+func (h *sentPacketHandler) MaybeQueueRescueRetransmission(hasNewData bool) {
+	if hasNewData || !h.inRecovery() {
+		return
+	}
+	el := h.packetHistory.Back()
+	if el == nil || h.RescueRxt != 0 {
+		return
+	}
+	h.RescueRxt = el.Value.PacketNumber
+	h.queuePacketForRetransmission(el)
 }
 
 // does NOT set packet.Retransmitted. This variable is not needed anymore
@@ -101,6 +215,7 @@ func (h *sentPacketHandler) queuePacketForRetransmission(packetElement *ackhandl
 	utils.Debugf("\tQueueing packet 0x%x for retransmission", packet.PacketNumber)
 	h.bytesInFlight -= packet.Length
 	h.retransmissionQueue = append(h.retransmissionQueue, packet)
+	h.pacer.OnPacketLost(packet.PacketNumber)
 
 	// If this is the lowest packet that hasn't been acked or retransmitted yet ...
 	if packet.PacketNumber == h.LargestInOrderAcked+1 {
@@ -142,7 +257,9 @@ func (h *sentPacketHandler) SentPacket(packet *ackhandlerlegacy.Packet) error {
 	h.bytesInFlight += packet.Length
 
 	h.lastSentPacketNumber = packet.PacketNumber
+	h.tlpSent = false
 	h.packetHistory.PushBack(*packet)
+	h.pacer.OnPacketSent(packet.PacketNumber, now)
 
 	h.congestion.OnPacketSent(
 		time.Now(),
@@ -152,6 +269,8 @@ func (h *sentPacketHandler) SentPacket(packet *ackhandlerlegacy.Packet) error {
 		true, /* TODO: is retransmittable */
 	)
 
+	h.fireProbe(0, 0)
+
 	return nil
 }
 
@@ -180,10 +299,12 @@ func (h *sentPacketHandler) ReceivedAck(ackFrame *frames.AckFrame, withPacketNum
 	}
 
 	h.LargestAcked = ackFrame.LargestAcked
+	wasInRecovery := h.inRecovery()
+	priorRecoveryPoint := h.recoveryPoint
 
 	var ackedPackets congestion.PacketVector
-	var lostPackets congestion.PacketVector
 	ackRangeIndex := 0
+	now := time.Now()
 
 	var el, elNext *ackhandlerlegacy.PacketElement
 	for el = h.packetHistory.Front(); el != nil; el = elNext {
@@ -192,23 +313,16 @@ func (h *sentPacketHandler) ReceivedAck(ackFrame *frames.AckFrame, withPacketNum
 		packet := el.Value
 		packetNumber := packet.PacketNumber
 
-		// NACK packets below the LowestAcked
+		// packets below the LowestAcked are left in the scoreboard; detectLosses decides their fate below
 		if packetNumber < ackFrame.LowestAcked {
-			p, err := h.nackPacket(el)
-			if err != nil {
-				return err
-			}
-			if p != nil {
-				lostPackets = append(lostPackets, congestion.PacketInfo{Number: p.PacketNumber, Length: p.Length})
-			}
 			continue
 		}
 
 		// Update the RTT
 		if packetNumber == h.LargestAcked {
-			timeDelta := time.Now().Sub(packet.SendTime)
+			timeDelta := now.Sub(packet.SendTime)
 			// TODO: Don't always update RTT
-			h.rttStats.UpdateRTT(timeDelta, ackFrame.DelayTime, time.Now())
+			h.rttStats.UpdateRTT(timeDelta, ackFrame.DelayTime, now)
 			if utils.Debug() {
 				utils.Debugf("\tEstimated RTT: %dms", h.rttStats.SmoothedRTT()/time.Millisecond)
 			}
@@ -230,48 +344,87 @@ func (h *sentPacketHandler) ReceivedAck(ackFrame *frames.AckFrame, withPacketNum
 				p := h.ackPacket(el)
 				if p != nil {
 					ackedPackets = append(ackedPackets, congestion.PacketInfo{Number: p.PacketNumber, Length: p.Length})
-				}
-			} else {
-				p, err := h.nackPacket(el)
-				if err != nil {
-					return err
-				}
-				if p != nil {
-					lostPackets = append(lostPackets, congestion.PacketInfo{Number: p.PacketNumber, Length: p.Length})
+					h.rack.OnPacketAcked(p.PacketNumber, p.SendTime, h.HighRxt, h.rttStats)
+					h.pacer.OnPacketAcked(p.PacketNumber, p.Length, now)
 				}
 			}
+			// else: packet is in a SACK gap, left in the scoreboard for detectLosses
 		} else {
 			p := h.ackPacket(el)
 			if p != nil {
 				ackedPackets = append(ackedPackets, congestion.PacketInfo{Number: p.PacketNumber, Length: p.Length})
+				h.rack.OnPacketAcked(p.PacketNumber, p.SendTime, h.HighRxt, h.rttStats)
+				h.pacer.OnPacketAcked(p.PacketNumber, p.Length, now)
 			}
 		}
 	}
 
+	// Each gap between SACK ranges is a block of packets reported as missing
+	// by this ACK, the SACK-world equivalent of a duplicate ACK arriving for
+	// the oldest unacked packet. Forward that count to the congestion
+	// controller so algorithms that react to dupack-style signals (distinct
+	// from the loss-based OnCongestionEvent call below) have something to see.
+	if ackFrame.HasMissingRanges() {
+		h.congestion.HandleNDupAcks(len(ackFrame.AckRanges) - 1)
+	}
+
+	lostPackets := h.detectLosses(now)
+
+	// Enter a new recovery phase only if we weren't already in one: while a
+	// packet number is <= recoveryPoint, further losses don't restart recovery.
+	if !wasInRecovery && len(lostPackets) > 0 {
+		h.recoveryPoint = h.lastSentPacketNumber
+		h.congestion.HandleLossRecoveryEntry()
+	}
+	// Exit recovery once an ACK covers recoveryPoint.
+	if h.recoveryPoint != 0 && h.LargestAcked >= h.recoveryPoint {
+		h.recoveryPoint = 0
+		h.RescueRxt = 0
+	}
+
 	h.garbageCollectSkippedPackets()
 
 	h.stopWaitingManager.ReceivedAck(ackFrame)
 
+	// Only report a lost packet to the congestion controller once: either it
+	// starts a brand new recovery phase (!wasInRecovery), or it's beyond the
+	// phase that's already open (priorRecoveryPoint) and so is genuinely a
+	// fresh, unrelated loss rather than one already accounted for by the
+	// phase's entry cwnd cut. A blanket !wasInRecovery gate would silently
+	// drop losses that arrive on the same ACK that closes out a phase.
+	var congestionLostPackets congestion.PacketVector
+	for _, p := range lostPackets {
+		if !wasInRecovery || p.Number > priorRecoveryPoint {
+			congestionLostPackets = append(congestionLostPackets, p)
+		}
+	}
 	h.congestion.OnCongestionEvent(
 		true, /* TODO: rtt updated */
 		h.BytesInFlight(),
 		ackedPackets,
-		lostPackets,
+		congestionLostPackets,
 	)
 
+	h.fireProbe(len(ackedPackets), len(lostPackets))
+
 	return nil
 }
 
-// ProbablyHasPacketForRetransmission returns if there is a packet queued for retransmission
+// ProbablyHasPacketForRetransmission returns if there is a packet queued for retransmission.
+// hasNewData should be true if the caller has new stream data ready to pack into a
+// packet; when it's false and a loss recovery phase is open, this queues a rescue
+// retransmission itself, so the phase's ACK clock doesn't stall on an otherwise idle
+// connection.
 // There is one case where it gets the answer wrong:
 // if a packet has already been queued for retransmission, but a belated ACK is received for this packet, this function will return true, although the packet will not be returend for retransmission by DequeuePacketForRetransmission()
-func (h *sentPacketHandler) ProbablyHasPacketForRetransmission() bool {
+func (h *sentPacketHandler) ProbablyHasPacketForRetransmission(hasNewData bool) bool {
 	h.maybeQueuePacketsRTO()
+	h.MaybeQueueRescueRetransmission(hasNewData)
 	return len(h.retransmissionQueue) > 0
 }
 
-func (h *sentPacketHandler) DequeuePacketForRetransmission() *ackhandlerlegacy.Packet {
-	if !h.ProbablyHasPacketForRetransmission() {
+func (h *sentPacketHandler) DequeuePacketForRetransmission(hasNewData bool) *ackhandlerlegacy.Packet {
+	if !h.ProbablyHasPacketForRetransmission(hasNewData) {
 		return nil
 	}
 
@@ -302,6 +455,31 @@ func (h *sentPacketHandler) CongestionAllowsSending() bool {
 	return h.BytesInFlight() <= h.congestion.GetCongestionWindow()
 }
 
+// TimeUntilSend returns how long the caller should wait before sending the
+// next packet, on top of the CongestionAllowsSending check: pacing spaces
+// packets out over a round trip instead of letting the whole congestion
+// window through in one burst right after an ACK arrives. The session loop
+// should schedule against this timer rather than spinning on
+// CongestionAllowsSending, but that loop lives outside this package and
+// doesn't call this yet - until that wiring lands, this method has no
+// caller outside the ackhandler package itself.
+func (h *sentPacketHandler) TimeUntilSend() time.Duration {
+	return h.pacer.TimeUntilSend(
+		time.Now(),
+		h.lastSentPacketTime,
+		h.congestion.GetCongestionWindow(),
+		h.rttStats.SmoothedRTT(),
+		h.congestion.PacingGain(),
+	)
+}
+
+// DeliveryRate returns the pacer's own windowed estimate of the connection's
+// delivery rate, independent of whatever the active congestion.SendAlgorithm
+// tracks internally.
+func (h *sentPacketHandler) DeliveryRate() congestion.Bandwidth {
+	return h.pacer.DeliveryRate()
+}
+
 func (h *sentPacketHandler) CheckForError() error {
 	length := len(h.retransmissionQueue) + h.packetHistory.Len()
 	if uint32(length) > protocol.MaxTrackedSentPackets {
@@ -311,10 +489,16 @@ func (h *sentPacketHandler) CheckForError() error {
 }
 
 func (h *sentPacketHandler) maybeQueuePacketsRTO() {
+	if h.maybeSendTLP() {
+		return
+	}
+
 	if time.Now().Before(h.TimeOfFirstRTO()) {
 		return
 	}
 
+	h.tlpSent = false
+
 	for el := h.packetHistory.Front(); el != nil; el = el.Next() {
 		packet := &el.Value
 		if packet.PacketNumber < h.LargestInOrderAcked {
@@ -333,6 +517,64 @@ func (h *sentPacketHandler) maybeQueuePacketsRTO() {
 	}
 }
 
+// defaultMaxAckDelay approximates the peer's max_ack_delay transport
+// parameter for the TLP timeout computation below; the real negotiated value
+// isn't threaded down into ackhandler.
+const defaultMaxAckDelay = 25 * time.Millisecond
+
+// getTLPTimeout returns the Tail Loss Probe timeout: max(2*SRTT, 1.5*SRTT +
+// maxAckDelay), per the TLP draft, capped below the RTO. The raw formula
+// isn't always shorter than getRTO() (e.g. at SRTT=500ms it works out to
+// ~1s against an RTO of ~600ms), and if it fired after the RTO, the RTO path
+// would already have retransmitted the tail packet itself, leaving
+// maybeSendTLP nothing to do; capping it guarantees the probe gets a chance
+// to trigger an ACK (and thus RACK/SACK-based loss detection) before the RTO
+// gives up and assumes the whole pipe is gone.
+func (h *sentPacketHandler) getTLPTimeout() time.Duration {
+	srtt := h.rttStats.SmoothedRTT()
+	if srtt == 0 {
+		return 0
+	}
+	timeout := utils.MaxDuration(2*srtt, srtt+srtt/2+defaultMaxAckDelay)
+	if rto := h.getRTO(); timeout >= rto {
+		timeout = rto / 2
+	}
+	return timeout
+}
+
+// TimeOfFirstTLP returns the earliest time at which maybeSendTLP would fire
+// the Tail Loss Probe, so a caller can schedule its timer against this
+// directly instead of only TimeOfFirstRTO.
+func (h *sentPacketHandler) TimeOfFirstTLP() time.Time {
+	timeout := h.getTLPTimeout()
+	if h.lastSentPacketTime.IsZero() || timeout == 0 {
+		return time.Time{}
+	}
+	return h.lastSentPacketTime.Add(timeout)
+}
+
+// maybeSendTLP retransmits the tail packet once, if the TLP timer has fired
+// and no probe is pending (tlpSent is false), to keep the ACK clock running on
+// an otherwise idle connection. It does not check the RTO deadline itself. It
+// reports whether it sent a probe, in which case the caller should not also
+// run the RTO path this round.
+func (h *sentPacketHandler) maybeSendTLP() bool {
+	if h.tlpSent {
+		return false
+	}
+	tlpTime := h.TimeOfFirstTLP()
+	if tlpTime.IsZero() || time.Now().Before(tlpTime) {
+		return false
+	}
+	el := h.packetHistory.Back()
+	if el == nil {
+		return false
+	}
+	h.tlpSent = true
+	h.queuePacketForRetransmission(el)
+	return true
+}
+
 func (h *sentPacketHandler) getRTO() time.Duration {
 	rto := h.congestion.RetransmissionDelay()
 	if rto == 0 {
@@ -348,6 +590,23 @@ func (h *sentPacketHandler) TimeOfFirstRTO() time.Time {
 	return h.lastSentPacketTime.Add(h.getRTO())
 }
 
+// TimeOfNextTimeout merges TimeOfFirstRTO, TimeOfFirstTLP and
+// TimeOfNextLossDetectionTimeout into the single earliest deadline the
+// caller needs to schedule against, rather than having to query all three
+// subsystems and take the minimum itself.
+func (h *sentPacketHandler) TimeOfNextTimeout() time.Time {
+	deadline := h.TimeOfFirstRTO()
+	for _, t := range []time.Time{h.TimeOfFirstTLP(), h.TimeOfNextLossDetectionTimeout()} {
+		if t.IsZero() {
+			continue
+		}
+		if deadline.IsZero() || t.Before(deadline) {
+			deadline = t
+		}
+	}
+	return deadline
+}
+
 func (h *sentPacketHandler) garbageCollectSkippedPackets() {
 	deleteIndex := 0
 	for i, p := range h.skippedPackets {
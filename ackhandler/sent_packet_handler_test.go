@@ -0,0 +1,114 @@
+package ackhandler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/ackhandlerlegacy"
+	"github.com/lucas-clemente/quic-go/congestion"
+	"github.com/lucas-clemente/quic-go/frames"
+	"github.com/lucas-clemente/quic-go/protocol"
+)
+
+// fakeSendAlgorithm records every OnCongestionEvent call's lost-packet
+// vector so a test can assert on which losses actually reached the
+// congestion controller.
+type fakeSendAlgorithm struct {
+	congestion.SendAlgorithm
+	congestionEvents []congestion.PacketVector
+}
+
+func (f *fakeSendAlgorithm) OnPacketSent(time.Time, protocol.ByteCount, protocol.PacketNumber, protocol.ByteCount, bool) {
+}
+
+func (f *fakeSendAlgorithm) OnCongestionEvent(_ bool, _ protocol.ByteCount, _, lostPackets congestion.PacketVector) {
+	f.congestionEvents = append(f.congestionEvents, lostPackets)
+}
+
+func (f *fakeSendAlgorithm) OnRetransmissionTimeout(bool) {}
+func (f *fakeSendAlgorithm) HandleNDupAcks(int)           {}
+func (f *fakeSendAlgorithm) HandleLossRecoveryEntry()     {}
+func (f *fakeSendAlgorithm) GetCongestionWindow() protocol.ByteCount {
+	return protocol.DefaultMaxCongestionWindow
+}
+func (f *fakeSendAlgorithm) RetransmissionDelay() time.Duration { return time.Second }
+func (f *fakeSendAlgorithm) SetPacingGain(float32)              {}
+func (f *fakeSendAlgorithm) PacingGain() float32                { return 1 }
+func (f *fakeSendAlgorithm) DeliveryRate() congestion.Bandwidth { return 0 }
+
+// lostNumbers flattens every OnCongestionEvent call's lostPackets vectors
+// into a single set of packet numbers reported across the test.
+func (f *fakeSendAlgorithm) lostNumbers() map[protocol.PacketNumber]bool {
+	out := map[protocol.PacketNumber]bool{}
+	for _, ev := range f.congestionEvents {
+		for _, p := range ev {
+			out[p.Number] = true
+		}
+	}
+	return out
+}
+
+// TestReceivedAckReportsLossBeyondClosingRecoveryPoint covers the scenario a
+// blanket `if !wasInRecovery` gate on congestionLostPackets got wrong: a loss
+// recovery phase entered by an earlier ACK, and a second ACK that both closes
+// out that phase (LargestAcked >= recoveryPoint) and independently uncovers a
+// fresh loss above recoveryPoint in the same call. That fresh loss must still
+// reach the congestion controller; it must not be silently swallowed just
+// because the handler "was in recovery" going into the ACK.
+func TestReceivedAckReportsLossBeyondClosingRecoveryPoint(t *testing.T) {
+	cc := &fakeSendAlgorithm{}
+	h := NewSentPacketHandler(WithCongestionControl(cc)).(*sentPacketHandler)
+
+	for pn := protocol.PacketNumber(1); pn <= 6; pn++ {
+		if err := h.SentPacket(&ackhandlerlegacy.Packet{PacketNumber: pn, Length: 100}); err != nil {
+			t.Fatalf("SentPacket(%d): %v", pn, err)
+		}
+	}
+
+	// ACK 1, 2 and 5; 3 and 4 are SACK gaps that immediately look lost to
+	// RACK (sent before the delivered packet 5, and the SRTT/4 reordering
+	// window is already over), entering recovery with recoveryPoint = 6
+	// (lastSentPacketNumber at the time).
+	firstAck := &frames.AckFrame{
+		LargestAcked: 5,
+		LowestAcked:  1,
+		AckRanges: []frames.AckRange{
+			{FirstPacketNumber: 5, LastPacketNumber: 5},
+			{FirstPacketNumber: 1, LastPacketNumber: 2},
+		},
+	}
+	if err := h.ReceivedAck(firstAck, 1); err != nil {
+		t.Fatalf("first ReceivedAck: %v", err)
+	}
+	if !h.inRecovery() {
+		t.Fatalf("expected handler to be in recovery after first ACK")
+	}
+	recoveryPoint := h.recoveryPoint
+
+	for pn := protocol.PacketNumber(7); pn <= 8; pn++ {
+		if err := h.SentPacket(&ackhandlerlegacy.Packet{PacketNumber: pn, Length: 100}); err != nil {
+			t.Fatalf("SentPacket(%d): %v", pn, err)
+		}
+	}
+
+	// ACK everything through recoveryPoint (closing the phase) and packet 8,
+	// leaving packet 7 as a fresh, unrelated loss above recoveryPoint.
+	secondAck := &frames.AckFrame{
+		LargestAcked: 8,
+		LowestAcked:  1,
+		AckRanges: []frames.AckRange{
+			{FirstPacketNumber: 8, LastPacketNumber: 8},
+			{FirstPacketNumber: 3, LastPacketNumber: recoveryPoint},
+		},
+	}
+	if err := h.ReceivedAck(secondAck, 2); err != nil {
+		t.Fatalf("second ReceivedAck: %v", err)
+	}
+
+	if h.inRecovery() {
+		t.Fatalf("expected recovery phase to have closed once the ACK covered recoveryPoint")
+	}
+	if lost := cc.lostNumbers(); !lost[7] {
+		t.Fatalf("packet 7 (beyond recoveryPoint=%d) was never reported to the congestion controller; got %v", recoveryPoint, lost)
+	}
+}
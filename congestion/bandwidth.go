@@ -0,0 +1,100 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/protocol"
+)
+
+// DefaultMaxDatagramSize is the datagram size congestion and pacing
+// computations assume when no path MTU has been discovered yet. It's
+// exported so ackhandler's pacer can share it instead of hardcoding its own
+// copy of the same number.
+const DefaultMaxDatagramSize = protocol.ByteCount(1452)
+
+// Bandwidth is a rate, in bytes per second.
+type Bandwidth uint64
+
+// BandwidthFromDelta computes the bandwidth implied by delivering bytes
+// bytes over the interval elapsed.
+func BandwidthFromDelta(bytes uint64, elapsed time.Duration) Bandwidth {
+	if elapsed <= 0 {
+		return 0
+	}
+	return Bandwidth(bytes) * Bandwidth(time.Second) / Bandwidth(elapsed)
+}
+
+// WindowedMaxFilter tracks the maximum sample observed over a sliding window
+// of rounds, as used by BBR to estimate the max bandwidth over the last few
+// round trips without being thrown off by a single low sample. It's exported
+// so that ackhandler's pacer can reuse it to turn raw per-ACK delivery-rate
+// samples into a stable bandwidth estimate, independent of which SendAlgorithm
+// is running.
+type WindowedMaxFilter struct {
+	windowLength uint64
+	estimates    [3]struct {
+		round uint64
+		value Bandwidth
+	}
+}
+
+// NewWindowedMaxFilter creates a WindowedMaxFilter that keeps the max sample
+// observed over the last windowLength rounds.
+func NewWindowedMaxFilter(windowLength uint64) *WindowedMaxFilter {
+	return &WindowedMaxFilter{windowLength: windowLength}
+}
+
+// Update feeds a new sample, tagged with the current round trip count, into
+// the filter, evicting samples that have aged out of the window.
+func (f *WindowedMaxFilter) Update(sample Bandwidth, round uint64) {
+	if f.estimates[0].round == 0 && f.estimates[0].value == 0 || sample >= f.estimates[0].value || round-f.estimates[2].round > f.windowLength {
+		f.estimates[0] = struct {
+			round uint64
+			value Bandwidth
+		}{round, sample}
+		f.estimates[1] = f.estimates[0]
+		f.estimates[2] = f.estimates[0]
+		return
+	}
+
+	if sample >= f.estimates[1].value {
+		f.estimates[1] = struct {
+			round uint64
+			value Bandwidth
+		}{round, sample}
+		f.estimates[2] = f.estimates[1]
+	} else if sample >= f.estimates[2].value {
+		f.estimates[2] = struct {
+			round uint64
+			value Bandwidth
+		}{round, sample}
+	}
+
+	if round-f.estimates[0].round > f.windowLength {
+		f.estimates[0] = f.estimates[1]
+		f.estimates[1] = f.estimates[2]
+		f.estimates[2] = struct {
+			round uint64
+			value Bandwidth
+		}{round, sample}
+	} else if round-f.estimates[1].round > f.windowLength {
+		f.estimates[1] = f.estimates[2]
+		f.estimates[2] = struct {
+			round uint64
+			value Bandwidth
+		}{round, sample}
+	} else if round-f.estimates[2].round > f.windowLength {
+		f.estimates[2] = struct {
+			round uint64
+			value Bandwidth
+		}{round, sample}
+	}
+}
+
+// Max returns the best of the filter's three kept estimates. A sample that
+// arrives below the kept estimates is not retained, so once a larger sample
+// ages out, Max can fall below the largest sample still inside the window
+// (it restarts from the newest sample).
+func (f *WindowedMaxFilter) Max() Bandwidth {
+	return f.estimates[0].value
+}
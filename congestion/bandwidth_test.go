@@ -0,0 +1,34 @@
+package congestion
+
+import "testing"
+
+func TestWindowedMaxFilterTracksMax(t *testing.T) {
+	f := NewWindowedMaxFilter(10)
+	f.Update(100, 1)
+	f.Update(50, 2)
+	f.Update(200, 3)
+	f.Update(10, 4)
+
+	if got := f.Max(); got != 200 {
+		t.Fatalf("Max() = %d, want 200", got)
+	}
+}
+
+func TestWindowedMaxFilterEvictsAgedSamples(t *testing.T) {
+	f := NewWindowedMaxFilter(3)
+	f.Update(200, 1)
+	for round := uint64(2); round <= 6; round++ {
+		f.Update(50, round)
+	}
+
+	if got := f.Max(); got != 50 {
+		t.Fatalf("Max() = %d, want 50 once the round-1 sample has aged out of the window", got)
+	}
+}
+
+func TestBandwidthFromDelta(t *testing.T) {
+	got := BandwidthFromDelta(1000, 0)
+	if got != 0 {
+		t.Fatalf("BandwidthFromDelta with zero elapsed = %d, want 0", got)
+	}
+}
@@ -0,0 +1,294 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/protocol"
+)
+
+// bbrMode is one of the four phases of the BBRv1 state machine.
+type bbrMode int
+
+const (
+	bbrModeStartup bbrMode = iota
+	bbrModeDrain
+	bbrModeProbeBW
+	bbrModeProbeRTT
+)
+
+const (
+	// bandwidthWindowSize is the number of roundTripCount steps the max
+	// bandwidth filter keeps a sample for (10, the BBR draft's default).
+	// roundTripCount advances once per ACK that acknowledges data, not once
+	// per round trip.
+	bandwidthWindowSize = 10
+	// minRTTExpiry is how long a min-RTT sample is trusted before BBR forces
+	// a PROBE_RTT phase to get a fresh one.
+	minRTTExpiry     = 10 * time.Second
+	probeRTTDuration = 200 * time.Millisecond
+
+	startupGrowthTarget = 1.25
+	drainGain           = 1 / startupGrowthTarget
+
+	defaultBBRInitialCwnd = protocol.ByteCount(32 * 1452)
+
+	// startupFullBwThreshold is the minimum round-over-round growth of
+	// maxBandwidthFilter.Max() that still counts as "still ramping up".
+	// Anything less is a sign the bottleneck's capacity has been found.
+	startupFullBwThreshold = 1.25
+	// startupFullBwCount is the number of consecutive rounds below
+	// startupFullBwThreshold growth required before STARTUP is considered
+	// plateaued and BBR moves on to DRAIN.
+	startupFullBwCount = 3
+)
+
+// probeBWGainCycle is BBR's 8-phase PROBE_BW pacing-gain cycle: one round of
+// probing up, one round draining the resulting queue, six rounds at unity.
+var probeBWGainCycle = [8]float32{1.25, 0.75, 1, 1, 1, 1, 1, 1}
+
+// bbrSender implements a simplified BBRv1: it paces sends at an estimate of
+// the path's delivery rate (rather than reacting to loss) and periodically
+// re-probes min RTT and max bandwidth, using WindowedMaxFilter to avoid
+// latching onto a single lucky/unlucky round.
+type bbrSender struct {
+	clock    Clock
+	rttStats *RTTStats
+
+	mode bbrMode
+
+	maxBandwidthFilter *WindowedMaxFilter
+	roundTripCount     uint64
+
+	minRTT            time.Duration
+	minRTTStamp       time.Time
+	probeRTTDoneStamp time.Time
+
+	cycleIndex int
+	cycleStart time.Time
+
+	pacingGain           float32
+	congestionWindowGain float32
+
+	congestionWindow    protocol.ByteCount
+	minCongestionWindow protocol.ByteCount
+	maxCongestionWindow protocol.ByteCount
+
+	largestSentPacketNumber  protocol.PacketNumber
+	largestAckedPacketNumber protocol.PacketNumber
+
+	lastSampleDeliveredBytes uint64
+	lastSampleTime           time.Time
+
+	// fullBandwidth and fullBandwidthCount track the STARTUP plateau check:
+	// fullBandwidth is the last round's bandwidth high-water mark, and
+	// fullBandwidthCount counts consecutive rounds that failed to beat it by
+	// startupFullBwThreshold.
+	fullBandwidth      Bandwidth
+	fullBandwidthCount int
+}
+
+var _ SendAlgorithm = &bbrSender{}
+
+// NewBBRSender creates a BBRv1 congestion controller.
+func NewBBRSender(clock Clock, rttStats *RTTStats, initialCongestionWindow, initialMaxCongestionWindow protocol.ByteCount) SendAlgorithm {
+	if initialCongestionWindow == 0 {
+		initialCongestionWindow = defaultBBRInitialCwnd
+	}
+	return &bbrSender{
+		clock:                clock,
+		rttStats:             rttStats,
+		mode:                 bbrModeStartup,
+		maxBandwidthFilter:   NewWindowedMaxFilter(bandwidthWindowSize),
+		pacingGain:           startupGrowthTarget,
+		congestionWindowGain: startupGrowthTarget,
+		congestionWindow:     initialCongestionWindow,
+		minCongestionWindow:  minCongestionWindow,
+		maxCongestionWindow:  initialMaxCongestionWindow,
+	}
+}
+
+func (b *bbrSender) OnPacketSent(sentTime time.Time, _ protocol.ByteCount, packetNumber protocol.PacketNumber, _ protocol.ByteCount, isRetransmittable bool) {
+	if !isRetransmittable {
+		return
+	}
+	b.largestSentPacketNumber = packetNumber
+}
+
+func (b *bbrSender) OnCongestionEvent(_ bool, bytesInFlight protocol.ByteCount, ackedPackets PacketVector, lostPackets PacketVector) {
+	now := b.clock.Now()
+
+	var ackedBytes uint64
+	for _, p := range ackedPackets {
+		if p.Number > b.largestAckedPacketNumber {
+			b.largestAckedPacketNumber = p.Number
+		}
+		ackedBytes += uint64(p.Length)
+	}
+
+	if ackedBytes > 0 {
+		b.roundTripCount++
+		b.updateBandwidthSample(ackedBytes, now)
+		// updateMode runs before updateMinRTT, so an expired minRTTStamp is
+		// seen (and PROBE_RTT entered) before updateMinRTT refreshes it.
+		b.updateMode(now)
+		b.updateMinRTT(now)
+		b.updateCongestionWindow(ackedBytes, bytesInFlight)
+	}
+
+	_ = lostPackets
+}
+
+// updateBandwidthSample folds newly-acked bytes into the delivery-rate
+// estimate and feeds the result into the windowed max filter BBR uses as its
+// estimate of bottleneck bandwidth (BtlBw).
+func (b *bbrSender) updateBandwidthSample(ackedBytes uint64, now time.Time) {
+	if !b.lastSampleTime.IsZero() {
+		elapsed := now.Sub(b.lastSampleTime)
+		if elapsed > 0 {
+			sample := BandwidthFromDelta(ackedBytes, elapsed)
+			b.maxBandwidthFilter.Update(sample, b.roundTripCount)
+		}
+	}
+	b.lastSampleTime = now
+	b.lastSampleDeliveredBytes += ackedBytes
+}
+
+// isStartupBandwidthPlateaued reports whether maxBandwidthFilter.Max() has
+// gone startupFullBwCount consecutive rounds without growing by at least
+// startupFullBwThreshold, BBR's signal that STARTUP has found the
+// bottleneck's capacity and should hand off to DRAIN.
+func (b *bbrSender) isStartupBandwidthPlateaued() bool {
+	bw := b.maxBandwidthFilter.Max()
+	if bw == 0 {
+		return false
+	}
+	if float64(bw) >= float64(b.fullBandwidth)*startupFullBwThreshold {
+		b.fullBandwidth = bw
+		b.fullBandwidthCount = 0
+		return false
+	}
+	b.fullBandwidthCount++
+	return b.fullBandwidthCount >= startupFullBwCount
+}
+
+func (b *bbrSender) updateMinRTT(now time.Time) {
+	rtt := b.rttStats.LatestRTT()
+	if rtt <= 0 {
+		return
+	}
+	if b.minRTT == 0 || rtt < b.minRTT || now.Sub(b.minRTTStamp) > minRTTExpiry {
+		b.minRTT = rtt
+		b.minRTTStamp = now
+	}
+}
+
+func (b *bbrSender) updateMode(now time.Time) {
+	switch b.mode {
+	case bbrModeStartup:
+		// Exit STARTUP once bandwidth stops growing appreciably: three
+		// consecutive rounds where maxBandwidthFilter.Max() grew by less
+		// than startupFullBwThreshold are taken as the bottleneck's capacity
+		// having been found, regardless of how many round trips that took.
+		if b.isStartupBandwidthPlateaued() {
+			b.mode = bbrModeDrain
+			b.pacingGain = drainGain
+			b.congestionWindowGain = startupGrowthTarget
+		}
+	case bbrModeDrain:
+		if b.bytesInFlightBelowTarget() {
+			b.enterProbeBW(now)
+		}
+	case bbrModeProbeBW:
+		if now.Sub(b.minRTTStamp) > minRTTExpiry {
+			b.mode = bbrModeProbeRTT
+			b.probeRTTDoneStamp = time.Time{}
+			b.pacingGain = 1
+			b.congestionWindowGain = 1
+			return
+		}
+		b.advanceProbeBWCycle(now)
+	case bbrModeProbeRTT:
+		if b.probeRTTDoneStamp.IsZero() {
+			b.probeRTTDoneStamp = now.Add(probeRTTDuration)
+			return
+		}
+		if now.After(b.probeRTTDoneStamp) {
+			b.minRTTStamp = now
+			b.enterProbeBW(now)
+		}
+	}
+}
+
+func (b *bbrSender) enterProbeBW(now time.Time) {
+	b.mode = bbrModeProbeBW
+	b.cycleIndex = 0
+	b.cycleStart = now
+	b.pacingGain = probeBWGainCycle[0]
+	b.congestionWindowGain = 2
+}
+
+func (b *bbrSender) advanceProbeBWCycle(now time.Time) {
+	if now.Sub(b.cycleStart) < b.minRTT {
+		return
+	}
+	b.cycleIndex = (b.cycleIndex + 1) % len(probeBWGainCycle)
+	b.cycleStart = now
+	b.pacingGain = probeBWGainCycle[b.cycleIndex]
+}
+
+// bytesInFlightBelowTarget approximates BBR's DRAIN exit condition: the
+// queue built up during STARTUP has drained back down to the BDP estimate.
+func (b *bbrSender) bytesInFlightBelowTarget() bool {
+	return b.congestionWindow <= b.bdp()
+}
+
+// bdp returns the bandwidth-delay product estimate: BtlBw * RTprop.
+func (b *bbrSender) bdp() protocol.ByteCount {
+	if b.minRTT == 0 {
+		return b.congestionWindow
+	}
+	return protocol.ByteCount(uint64(b.maxBandwidthFilter.Max()) * uint64(b.minRTT) / uint64(time.Second))
+}
+
+func (b *bbrSender) updateCongestionWindow(ackedBytes uint64, bytesInFlight protocol.ByteCount) {
+	target := protocol.ByteCount(float32(b.bdp()) * b.congestionWindowGain)
+	if target < b.minCongestionWindow {
+		target = b.minCongestionWindow
+	}
+	if target > b.maxCongestionWindow {
+		target = b.maxCongestionWindow
+	}
+	b.congestionWindow = target
+}
+
+func (b *bbrSender) HandleLossRecoveryEntry() {
+	// BBR deliberately does not react to loss the way loss-based controllers
+	// do; it relies on its bandwidth/RTT model instead.
+}
+
+func (b *bbrSender) HandleNDupAcks(nDupAcks int) {}
+
+func (b *bbrSender) OnRetransmissionTimeout(packetsRetransmitted bool) {}
+
+func (b *bbrSender) GetCongestionWindow() protocol.ByteCount {
+	return b.congestionWindow
+}
+
+func (b *bbrSender) RetransmissionDelay() time.Duration {
+	if b.rttStats.SmoothedRTT() == 0 {
+		return 0
+	}
+	return 2 * b.rttStats.SmoothedRTT()
+}
+
+func (b *bbrSender) SetPacingGain(gain float32) {
+	b.pacingGain = gain
+}
+
+func (b *bbrSender) PacingGain() float32 {
+	return b.pacingGain
+}
+
+func (b *bbrSender) DeliveryRate() Bandwidth {
+	return b.maxBandwidthFilter.Max()
+}
@@ -0,0 +1,63 @@
+package congestion
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestBBRSender(clock *fakeClock) *bbrSender {
+	sender := NewBBRSender(clock, &RTTStats{}, 0, 1000*DefaultMaxDatagramSize)
+	return sender.(*bbrSender)
+}
+
+func TestBBRStartupPlateauExitsAfterThreeFlatRounds(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	b := newTestBBRSender(clock)
+
+	const rtt = 50 * time.Millisecond
+	// Growing bandwidth samples: STARTUP must stay open while the filter's
+	// max keeps climbing by more than startupFullBwThreshold per round.
+	for _, bw := range []Bandwidth{100, 200, 400} {
+		clock.now = clock.now.Add(rtt)
+		b.rttStats.UpdateRTT(rtt, 0, clock.now)
+		b.maxBandwidthFilter.Update(bw, b.roundTripCount+1)
+		b.roundTripCount++
+		b.updateMode(clock.now)
+		if b.mode != bbrModeStartup {
+			t.Fatalf("mode = %v after growing sample %d, want still bbrModeStartup", b.mode, bw)
+		}
+	}
+
+	// Three consecutive flat rounds (no sample beats the existing max by
+	// startupFullBwThreshold) should trigger the exit to DRAIN.
+	for i := 0; i < startupFullBwCount; i++ {
+		clock.now = clock.now.Add(rtt)
+		b.roundTripCount++
+		b.maxBandwidthFilter.Update(400, b.roundTripCount)
+		b.updateMode(clock.now)
+	}
+
+	if b.mode != bbrModeDrain {
+		t.Fatalf("mode = %v after %d flat rounds, want bbrModeDrain", b.mode, startupFullBwCount)
+	}
+}
+
+func TestBBRIsStartupBandwidthPlateauedResetsOnGrowth(t *testing.T) {
+	b := newTestBBRSender(&fakeClock{now: time.Now()})
+
+	b.maxBandwidthFilter.Update(100, 1)
+	if b.isStartupBandwidthPlateaued() {
+		t.Fatalf("should not be plateaued on the first sample")
+	}
+	b.fullBandwidthCount = startupFullBwCount - 1
+
+	// A fresh sample that grows past the threshold should reset the count,
+	// not immediately report plateaued.
+	b.maxBandwidthFilter.Update(1000, 2)
+	if b.isStartupBandwidthPlateaued() {
+		t.Fatalf("growth past startupFullBwThreshold should reset fullBandwidthCount, not report plateaued")
+	}
+	if b.fullBandwidthCount != 0 {
+		t.Fatalf("fullBandwidthCount = %d after growth, want reset to 0", b.fullBandwidthCount)
+	}
+}
@@ -0,0 +1,18 @@
+package congestion
+
+import "time"
+
+// Clock abstracts the system clock, so that tests can inject a fake one.
+type Clock interface {
+	Now() time.Time
+}
+
+// DefaultClock implements Clock using the real system clock.
+type DefaultClock struct{}
+
+var _ Clock = DefaultClock{}
+
+// Now returns the current time.
+func (DefaultClock) Now() time.Time {
+	return time.Now()
+}
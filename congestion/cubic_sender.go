@@ -0,0 +1,217 @@
+package congestion
+
+import (
+	"math"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/protocol"
+	"github.com/lucas-clemente/quic-go/utils"
+)
+
+const (
+	// betaCubic is the multiplicative window decrease on a loss event.
+	betaCubic = 0.7
+	// cubicC is the CUBIC scaling constant from RFC 8312.
+	cubicC = 0.4
+
+	maxDatagramSize = DefaultMaxDatagramSize
+
+	minCongestionWindow = 2 * maxDatagramSize
+)
+
+// cubicSender implements the CUBIC congestion control algorithm (RFC 8312).
+// It does not implement RFC 8312's TCP-friendly region: outside slow start
+// the window follows the cubic growth function alone.
+type cubicSender struct {
+	clock    Clock
+	rttStats *RTTStats
+
+	hybridSlowStart hybridSlowStart
+	pacingGain      float32
+
+	congestionWindow    protocol.ByteCount
+	minCongestionWindow protocol.ByteCount
+	maxCongestionWindow protocol.ByteCount
+
+	slowStartThreshold protocol.ByteCount
+
+	largestSentPacketNumber  protocol.PacketNumber
+	largestAckedPacketNumber protocol.PacketNumber
+	largestSentAtLastCutback protocol.PacketNumber
+
+	lastCutbackExitedSlowstart bool
+
+	// CUBIC state
+	epoch                       time.Time
+	lastMaxCongestionWindow     protocol.ByteCount
+	originPointCongestionWindow protocol.ByteCount
+	kDuration                   float64
+}
+
+var _ SendAlgorithm = &cubicSender{}
+
+// NewCubicSender creates a CUBIC congestion controller, as used by Chromium.
+func NewCubicSender(clock Clock, rttStats *RTTStats, initialCongestionWindow, initialMaxCongestionWindow protocol.ByteCount) SendAlgorithm {
+	return &cubicSender{
+		clock:               clock,
+		rttStats:            rttStats,
+		congestionWindow:    initialCongestionWindow,
+		minCongestionWindow: minCongestionWindow,
+		maxCongestionWindow: initialMaxCongestionWindow,
+		slowStartThreshold:  initialMaxCongestionWindow,
+		pacingGain:          1,
+	}
+}
+
+func (c *cubicSender) OnPacketSent(sentTime time.Time, _ protocol.ByteCount, packetNumber protocol.PacketNumber, bytes protocol.ByteCount, isRetransmittable bool) {
+	if !isRetransmittable {
+		return
+	}
+	c.largestSentPacketNumber = packetNumber
+	c.hybridSlowStart.OnPacketSent(packetNumber)
+}
+
+func (c *cubicSender) inSlowStart() bool {
+	return c.congestionWindow < c.slowStartThreshold
+}
+
+func (c *cubicSender) inRecovery() bool {
+	return c.largestAckedPacketNumber <= c.largestSentAtLastCutback && c.largestSentAtLastCutback != 0
+}
+
+func (c *cubicSender) OnCongestionEvent(rttUpdated bool, bytesInFlight protocol.ByteCount, ackedPackets PacketVector, lostPackets PacketVector) {
+	if len(lostPackets) > 0 {
+		c.onPacketLost(lostPackets[len(lostPackets)-1].Number, bytesInFlight)
+	}
+	for _, p := range ackedPackets {
+		c.onPacketAcked(p.Number, p.Length, bytesInFlight)
+	}
+}
+
+func (c *cubicSender) onPacketAcked(ackedPacketNumber protocol.PacketNumber, ackedBytes, bytesInFlight protocol.ByteCount) {
+	if ackedPacketNumber > c.largestAckedPacketNumber {
+		c.largestAckedPacketNumber = ackedPacketNumber
+	}
+	if c.inRecovery() {
+		return
+	}
+	c.maybeIncreaseCwnd(ackedBytes, bytesInFlight)
+	if c.inSlowStart() {
+		if c.hybridSlowStart.OnPacketAcked(ackedPacketNumber, c.rttStats.LatestRTT()) {
+			c.slowStartThreshold = c.congestionWindow
+		}
+	}
+}
+
+func (c *cubicSender) onPacketLost(packetNumber protocol.PacketNumber, bytesInFlight protocol.ByteCount) {
+	// Only cut the window once per loss event: everything at or below
+	// largestSentAtLastCutback was already accounted for.
+	if packetNumber <= c.largestSentAtLastCutback {
+		return
+	}
+	c.lastCutbackExitedSlowstart = c.inSlowStart()
+	c.largestSentAtLastCutback = c.largestSentPacketNumber
+
+	c.epoch = time.Time{}
+	c.lastMaxCongestionWindow = c.congestionWindow
+	c.congestionWindow = protocol.ByteCount(float64(c.congestionWindow) * betaCubic)
+	if c.congestionWindow < c.minCongestionWindow {
+		c.congestionWindow = c.minCongestionWindow
+	}
+	c.slowStartThreshold = c.congestionWindow
+}
+
+func (c *cubicSender) HandleLossRecoveryEntry() {
+	// CUBIC already debounces window cuts per-loss-event via largestSentAtLastCutback.
+}
+
+func (c *cubicSender) HandleNDupAcks(nDupAcks int) {}
+
+func (c *cubicSender) maybeIncreaseCwnd(ackedBytes, bytesInFlight protocol.ByteCount) {
+	if !c.isCwndLimited(bytesInFlight) {
+		return
+	}
+	if c.congestionWindow >= c.maxCongestionWindow {
+		return
+	}
+	if c.inSlowStart() {
+		c.congestionWindow += maxDatagramSize
+		return
+	}
+	c.congestionWindow = c.cubicCongestionWindow()
+}
+
+func (c *cubicSender) isCwndLimited(bytesInFlight protocol.ByteCount) bool {
+	if bytesInFlight >= c.congestionWindow {
+		return true
+	}
+	availableBytes := c.congestionWindow - bytesInFlight
+	return availableBytes <= 3*maxDatagramSize
+}
+
+// cubicCongestionWindow implements the CUBIC window growth function from
+// RFC 8312 section 4.1: W(t) = C*(t-K)^3 + W_max.
+func (c *cubicSender) cubicCongestionWindow() protocol.ByteCount {
+	now := c.clock.Now()
+	if c.epoch.IsZero() {
+		c.epoch = now
+		if c.lastMaxCongestionWindow <= c.congestionWindow {
+			c.kDuration = 0
+			c.originPointCongestionWindow = c.congestionWindow
+		} else {
+			c.kDuration = math.Cbrt(float64(c.lastMaxCongestionWindow-c.congestionWindow) / cubicC / float64(maxDatagramSize))
+			c.originPointCongestionWindow = c.lastMaxCongestionWindow
+		}
+	}
+
+	elapsed := now.Sub(c.epoch).Seconds()
+	offset := elapsed - c.kDuration
+	delta := cubicC * offset * offset * offset * float64(maxDatagramSize)
+
+	target := float64(c.originPointCongestionWindow) + delta
+	if target < float64(c.congestionWindow) {
+		target = float64(c.congestionWindow)
+	}
+	return protocol.ByteCount(target)
+}
+
+func (c *cubicSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
+	c.epoch = time.Time{}
+	if !packetsRetransmitted {
+		return
+	}
+	c.hybridSlowStart.Restart()
+	c.slowStartThreshold = c.congestionWindow / 2
+	c.congestionWindow = c.minCongestionWindow
+}
+
+func (c *cubicSender) GetCongestionWindow() protocol.ByteCount {
+	return c.congestionWindow
+}
+
+func (c *cubicSender) RetransmissionDelay() time.Duration {
+	if c.rttStats.SmoothedRTT() == 0 {
+		return 0
+	}
+	return c.rttStats.SmoothedRTT() + utils.MaxDuration(c.rttStats.MeanDeviation()*4, protocol.MinRetransmissionTime/2)
+}
+
+func (c *cubicSender) SetPacingGain(gain float32) {
+	c.pacingGain = gain
+}
+
+func (c *cubicSender) PacingGain() float32 {
+	return c.pacingGain
+}
+
+// DeliveryRate returns cwnd/SRTT as a sensible default delivery-rate
+// estimate for a loss-based controller that, unlike BBR, doesn't otherwise
+// track one: it's the same pacing-rate formula ackhandler.pacer derives its
+// own TimeUntilSend calculation from.
+func (c *cubicSender) DeliveryRate() Bandwidth {
+	srtt := c.rttStats.SmoothedRTT()
+	if srtt <= 0 {
+		return 0
+	}
+	return BandwidthFromDelta(uint64(c.congestionWindow), srtt)
+}
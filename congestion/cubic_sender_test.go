@@ -0,0 +1,80 @@
+package congestion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/protocol"
+)
+
+// fakeClock is a controllable Clock for deterministic CUBIC timing tests.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestCubicSender(clock *fakeClock) *cubicSender {
+	sender := NewCubicSender(clock, &RTTStats{}, 100*DefaultMaxDatagramSize, 1000*DefaultMaxDatagramSize)
+	return sender.(*cubicSender)
+}
+
+func TestCubicOnPacketLostCutsWindowByBeta(t *testing.T) {
+	cs := newTestCubicSender(&fakeClock{now: time.Now()})
+	cs.largestSentPacketNumber = 10
+	before := cs.GetCongestionWindow()
+
+	cs.OnCongestionEvent(true, 0, nil, PacketVector{{Number: 5, Length: DefaultMaxDatagramSize}})
+
+	want := protocol.ByteCount(float64(before) * betaCubic)
+	if got := cs.GetCongestionWindow(); got != want {
+		t.Fatalf("GetCongestionWindow() after loss = %d, want %d (beta cut of %d)", got, want, before)
+	}
+	if cs.slowStartThreshold != want {
+		t.Fatalf("slowStartThreshold after loss = %d, want %d", cs.slowStartThreshold, want)
+	}
+}
+
+func TestCubicOnPacketLostRespectsMinCongestionWindow(t *testing.T) {
+	cs := newTestCubicSender(&fakeClock{now: time.Now()})
+	cs.congestionWindow = minCongestionWindow
+	cs.largestSentPacketNumber = 10
+
+	cs.OnCongestionEvent(true, 0, nil, PacketVector{{Number: 5, Length: DefaultMaxDatagramSize}})
+
+	if got := cs.GetCongestionWindow(); got != minCongestionWindow {
+		t.Fatalf("GetCongestionWindow() = %d, want the minCongestionWindow floor of %d", got, minCongestionWindow)
+	}
+}
+
+func TestCubicOnPacketLostOnlyCutsOncePerEvent(t *testing.T) {
+	cs := newTestCubicSender(&fakeClock{now: time.Now()})
+	cs.largestSentPacketNumber = 10
+
+	cs.OnCongestionEvent(true, 0, nil, PacketVector{{Number: 5, Length: DefaultMaxDatagramSize}})
+	afterFirstCut := cs.GetCongestionWindow()
+
+	// A second, lower-numbered loss within the same event (largestSentAtLastCutback
+	// already covers packet 5) must not cut the window again.
+	cs.OnCongestionEvent(true, 0, nil, PacketVector{{Number: 3, Length: DefaultMaxDatagramSize}})
+
+	if got := cs.GetCongestionWindow(); got != afterFirstCut {
+		t.Fatalf("GetCongestionWindow() after redundant loss = %d, want unchanged %d", got, afterFirstCut)
+	}
+}
+
+func TestCubicCongestionWindowGrowsPastOriginPointOverTime(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	cs := newTestCubicSender(clock)
+	cs.congestionWindow = 100 * DefaultMaxDatagramSize
+	cs.lastMaxCongestionWindow = 140 * DefaultMaxDatagramSize
+
+	immediate := cs.cubicCongestionWindow()
+	if immediate != cs.congestionWindow {
+		t.Fatalf("cubicCongestionWindow() at t=0 = %d, want the current window %d (growth hasn't caught up to the prior max yet)", immediate, cs.congestionWindow)
+	}
+
+	clock.now = clock.now.Add(10 * time.Second)
+	later := cs.cubicCongestionWindow()
+	if later <= cs.originPointCongestionWindow {
+		t.Fatalf("cubicCongestionWindow() after 10s = %d, want growth past the origin point %d", later, cs.originPointCongestionWindow)
+	}
+}
@@ -0,0 +1,90 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/protocol"
+)
+
+const (
+	// hystartMinSamples is the number of RTT samples a round must accumulate
+	// before hybridSlowStart will act on them, so a single early sample can't
+	// trigger an exit.
+	hystartMinSamples = 8
+	// hystartDelayFactor is the divisor applied to the previous round's min
+	// RTT: an increase of at least lastRoundMinRTT/hystartDelayFactor
+	// (clamped to [hystartDelayMinThreshold, hystartDelayMaxThreshold]) is
+	// treated as queuing delay instead of noise.
+	hystartDelayFactor = 8
+
+	hystartDelayMinThreshold = 4 * time.Millisecond
+	hystartDelayMaxThreshold = 16 * time.Millisecond
+)
+
+// hybridSlowStart implements a simplified HyStart: it watches for an RTT
+// increase within a round, the signature of a queue building up at the
+// bottleneck, and signals that slow start should end before a loss forces it
+// to - the same early-exit Linux and Chromium's CUBIC use to avoid
+// overshooting the path's capacity.
+type hybridSlowStart struct {
+	started         bool
+	endPacketNumber protocol.PacketNumber
+
+	rttSampleCount  int
+	currentMinRTT   time.Duration
+	lastRoundMinRTT time.Duration
+}
+
+// OnPacketSent records the first packet number sent since the last round
+// started, so a subsequent OnPacketAcked can tell when a full round-trip has
+// elapsed.
+func (s *hybridSlowStart) OnPacketSent(packetNumber protocol.PacketNumber) {
+	if s.started {
+		return
+	}
+	s.started = true
+	s.endPacketNumber = packetNumber
+}
+
+// OnPacketAcked folds latestRTT into the current round's min-RTT sample and
+// reports whether the accumulated samples show enough of an RTT increase
+// over the previous round that slow start should exit now.
+func (s *hybridSlowStart) OnPacketAcked(ackedPacketNumber protocol.PacketNumber, latestRTT time.Duration) (shouldExitSlowStart bool) {
+	if latestRTT > 0 {
+		if s.currentMinRTT == 0 || latestRTT < s.currentMinRTT {
+			s.currentMinRTT = latestRTT
+		}
+		s.rttSampleCount++
+	}
+
+	if s.rttSampleCount >= hystartMinSamples && s.lastRoundMinRTT > 0 {
+		threshold := s.lastRoundMinRTT / hystartDelayFactor
+		if threshold < hystartDelayMinThreshold {
+			threshold = hystartDelayMinThreshold
+		}
+		if threshold > hystartDelayMaxThreshold {
+			threshold = hystartDelayMaxThreshold
+		}
+		if s.currentMinRTT >= s.lastRoundMinRTT+threshold {
+			shouldExitSlowStart = true
+		}
+	}
+
+	if ackedPacketNumber >= s.endPacketNumber {
+		s.started = false
+		s.lastRoundMinRTT = s.currentMinRTT
+		s.currentMinRTT = 0
+		s.rttSampleCount = 0
+	}
+
+	return shouldExitSlowStart
+}
+
+// Restart resets slow-start round tracking, e.g. after an RTO.
+func (s *hybridSlowStart) Restart() {
+	s.started = false
+	s.endPacketNumber = 0
+	s.rttSampleCount = 0
+	s.currentMinRTT = 0
+	s.lastRoundMinRTT = 0
+}
@@ -0,0 +1,94 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/protocol"
+)
+
+// PacketInfo carries the minimal per-packet information a SendAlgorithm
+// needs in order to update its state when a packet is acked or declared lost.
+type PacketInfo struct {
+	Number protocol.PacketNumber
+	Length protocol.ByteCount
+}
+
+// PacketVector is a list of packets acked or lost as part of a single
+// congestion event.
+type PacketVector []PacketInfo
+
+// SendAlgorithm is implemented by pluggable congestion controllers. The
+// sentPacketHandler calls into it on every send and ACK, and never assumes
+// anything about the controller's internals beyond this interface, so that
+// CUBIC, Reno and BBR (and anything added later) can be swapped in without
+// touching ack handling itself.
+type SendAlgorithm interface {
+	// OnPacketSent is called when a packet is sent, whether or not it's
+	// retransmittable.
+	OnPacketSent(sentTime time.Time, bytesInFlight protocol.ByteCount, packetNumber protocol.PacketNumber, bytes protocol.ByteCount, isRetransmittable bool)
+	// OnCongestionEvent is called once per ACK or loss-detection pass, with
+	// the packets that were newly acked and/or declared lost as a result.
+	OnCongestionEvent(rttUpdated bool, bytesInFlight protocol.ByteCount, ackedPackets PacketVector, lostPackets PacketVector)
+	// OnRetransmissionTimeout is called when the RTO fires. packetsRetransmitted
+	// is true if this resulted in a retransmission being queued.
+	OnRetransmissionTimeout(packetsRetransmitted bool)
+	// HandleNDupAcks is called when nDupAcks SACKed packet ranges have been
+	// seen above a packet that hasn't been acked, i.e. duplicate-ACK-style
+	// loss signal independent of RTO.
+	HandleNDupAcks(nDupAcks int)
+	// HandleLossRecoveryEntry is called exactly once when the sentPacketHandler
+	// enters a new loss recovery phase, so the controller can cut its window
+	// once per loss event rather than once per lost packet.
+	HandleLossRecoveryEntry()
+	// GetCongestionWindow returns the current congestion window, in bytes.
+	GetCongestionWindow() protocol.ByteCount
+	// RetransmissionDelay returns the current RTO duration.
+	RetransmissionDelay() time.Duration
+	// SetPacingGain sets the multiplier applied to the pacing rate derived
+	// from cwnd/SRTT. Controllers that don't vary their gain (e.g. Reno) may
+	// treat this as a no-op.
+	SetPacingGain(gain float32)
+	// PacingGain returns the multiplier currently in effect, for the pacer to
+	// apply on top of cwnd/SRTT. BBR varies this as it cycles through its
+	// PROBE_BW phases; other controllers typically just return the last
+	// value SetPacingGain was called with (1 by default).
+	PacingGain() float32
+	// DeliveryRate returns the controller's current estimate of the
+	// connection's delivery rate, in bytes per second.
+	DeliveryRate() Bandwidth
+}
+
+// CongestionControlAlgorithm identifies a pluggable SendAlgorithm
+// implementation. This is the value a Config.CongestionControl field is
+// expected to carry through to NewSendAlgorithm.
+type CongestionControlAlgorithm int
+
+const (
+	// CongestionControlCubic selects the CUBIC congestion controller. This is the default.
+	CongestionControlCubic CongestionControlAlgorithm = iota
+	// CongestionControlReno selects the classic NewReno (AIMD) congestion controller.
+	CongestionControlReno
+	// CongestionControlBBR selects the BBRv1 congestion controller.
+	CongestionControlBBR
+)
+
+// NewSendAlgorithm builds the SendAlgorithm selected by algo, ready to hand
+// to ackhandler.WithCongestionControl. rttStats must be the same RTTStats
+// instance the sentPacketHandler updates, so the controller always sees the
+// connection's current RTT estimate.
+func NewSendAlgorithm(
+	algo CongestionControlAlgorithm,
+	clock Clock,
+	rttStats *RTTStats,
+	initialCongestionWindow protocol.ByteCount,
+	initialMaxCongestionWindow protocol.ByteCount,
+) SendAlgorithm {
+	switch algo {
+	case CongestionControlReno:
+		return NewRenoSender(clock, rttStats, initialCongestionWindow, initialMaxCongestionWindow)
+	case CongestionControlBBR:
+		return NewBBRSender(clock, rttStats, initialCongestionWindow, initialMaxCongestionWindow)
+	default:
+		return NewCubicSender(clock, rttStats, initialCongestionWindow, initialMaxCongestionWindow)
+	}
+}
@@ -0,0 +1,163 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/protocol"
+	"github.com/lucas-clemente/quic-go/utils"
+)
+
+// renoBeta is the multiplicative decrease factor NewReno applies to the
+// congestion window on a loss event (RFC 5681).
+const renoBeta = 0.5
+
+// renoSender implements classic NewReno: slow start doubles the window every
+// round trip, congestion avoidance grows it by one segment per round trip,
+// and a loss event halves it. It exists mainly as a well-understood baseline
+// to compare CUBIC and BBR against on the same connection.
+type renoSender struct {
+	clock    Clock
+	rttStats *RTTStats
+
+	hybridSlowStart hybridSlowStart
+	pacingGain      float32
+
+	congestionWindow    protocol.ByteCount
+	minCongestionWindow protocol.ByteCount
+	maxCongestionWindow protocol.ByteCount
+	slowStartThreshold  protocol.ByteCount
+
+	largestSentPacketNumber  protocol.PacketNumber
+	largestAckedPacketNumber protocol.PacketNumber
+	largestSentAtLastCutback protocol.PacketNumber
+
+	// congestionWindowRemainder tracks the fractional segment accumulated
+	// while growing the window by MSS^2/cwnd per acked segment during
+	// congestion avoidance.
+	congestionWindowRemainder protocol.ByteCount
+}
+
+var _ SendAlgorithm = &renoSender{}
+
+// NewRenoSender creates a classic NewReno (AIMD) congestion controller.
+func NewRenoSender(clock Clock, rttStats *RTTStats, initialCongestionWindow, initialMaxCongestionWindow protocol.ByteCount) SendAlgorithm {
+	return &renoSender{
+		clock:               clock,
+		rttStats:            rttStats,
+		congestionWindow:    initialCongestionWindow,
+		minCongestionWindow: minCongestionWindow,
+		maxCongestionWindow: initialMaxCongestionWindow,
+		slowStartThreshold:  initialMaxCongestionWindow,
+		pacingGain:          1,
+	}
+}
+
+func (r *renoSender) OnPacketSent(_ time.Time, _ protocol.ByteCount, packetNumber protocol.PacketNumber, _ protocol.ByteCount, isRetransmittable bool) {
+	if !isRetransmittable {
+		return
+	}
+	r.largestSentPacketNumber = packetNumber
+	r.hybridSlowStart.OnPacketSent(packetNumber)
+}
+
+func (r *renoSender) inSlowStart() bool {
+	return r.congestionWindow < r.slowStartThreshold
+}
+
+func (r *renoSender) inRecovery() bool {
+	return r.largestAckedPacketNumber <= r.largestSentAtLastCutback && r.largestSentAtLastCutback != 0
+}
+
+func (r *renoSender) OnCongestionEvent(_ bool, bytesInFlight protocol.ByteCount, ackedPackets PacketVector, lostPackets PacketVector) {
+	if len(lostPackets) > 0 {
+		r.onPacketLost(lostPackets[len(lostPackets)-1].Number)
+	}
+	for _, p := range ackedPackets {
+		r.onPacketAcked(p.Number, bytesInFlight)
+	}
+}
+
+func (r *renoSender) onPacketAcked(ackedPacketNumber protocol.PacketNumber, bytesInFlight protocol.ByteCount) {
+	if ackedPacketNumber > r.largestAckedPacketNumber {
+		r.largestAckedPacketNumber = ackedPacketNumber
+	}
+	if r.inRecovery() {
+		return
+	}
+	if bytesInFlight < r.congestionWindow {
+		// Not CWND-limited right now, don't grow the window.
+		return
+	}
+	if r.congestionWindow >= r.maxCongestionWindow {
+		return
+	}
+	if r.inSlowStart() {
+		r.congestionWindow += maxDatagramSize
+		if r.hybridSlowStart.OnPacketAcked(ackedPacketNumber, r.rttStats.LatestRTT()) {
+			r.slowStartThreshold = r.congestionWindow
+		}
+		return
+	}
+	// Congestion avoidance: grow by one segment per round trip.
+	r.congestionWindowRemainder += maxDatagramSize * maxDatagramSize / r.congestionWindow
+	if r.congestionWindowRemainder >= maxDatagramSize {
+		r.congestionWindow += maxDatagramSize
+		r.congestionWindowRemainder -= maxDatagramSize
+	}
+}
+
+func (r *renoSender) onPacketLost(packetNumber protocol.PacketNumber) {
+	if packetNumber <= r.largestSentAtLastCutback {
+		return
+	}
+	r.largestSentAtLastCutback = r.largestSentPacketNumber
+	r.congestionWindow = protocol.ByteCount(float64(r.congestionWindow) * renoBeta)
+	if r.congestionWindow < r.minCongestionWindow {
+		r.congestionWindow = r.minCongestionWindow
+	}
+	r.slowStartThreshold = r.congestionWindow
+}
+
+func (r *renoSender) HandleLossRecoveryEntry() {}
+
+func (r *renoSender) HandleNDupAcks(nDupAcks int) {}
+
+func (r *renoSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
+	if !packetsRetransmitted {
+		return
+	}
+	r.hybridSlowStart.Restart()
+	r.slowStartThreshold = r.congestionWindow / 2
+	r.congestionWindow = r.minCongestionWindow
+}
+
+func (r *renoSender) GetCongestionWindow() protocol.ByteCount {
+	return r.congestionWindow
+}
+
+func (r *renoSender) RetransmissionDelay() time.Duration {
+	if r.rttStats.SmoothedRTT() == 0 {
+		return 0
+	}
+	return r.rttStats.SmoothedRTT() + utils.MaxDuration(r.rttStats.MeanDeviation()*4, protocol.MinRetransmissionTime/2)
+}
+
+func (r *renoSender) SetPacingGain(gain float32) {
+	r.pacingGain = gain
+}
+
+func (r *renoSender) PacingGain() float32 {
+	return r.pacingGain
+}
+
+// DeliveryRate returns cwnd/SRTT as a sensible default delivery-rate
+// estimate for a loss-based controller that, unlike BBR, doesn't otherwise
+// track one: it's the same pacing-rate formula ackhandler.pacer derives its
+// own TimeUntilSend calculation from.
+func (r *renoSender) DeliveryRate() Bandwidth {
+	srtt := r.rttStats.SmoothedRTT()
+	if srtt <= 0 {
+		return 0
+	}
+	return BandwidthFromDelta(uint64(r.congestionWindow), srtt)
+}
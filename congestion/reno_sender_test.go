@@ -0,0 +1,75 @@
+package congestion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/protocol"
+)
+
+func newTestRenoSender() *renoSender {
+	sender := NewRenoSender(DefaultClock{}, &RTTStats{}, 100*DefaultMaxDatagramSize, 1000*DefaultMaxDatagramSize)
+	return sender.(*renoSender)
+}
+
+func TestRenoSlowStartGrowsByOneSegmentPerAck(t *testing.T) {
+	r := newTestRenoSender()
+	before := r.GetCongestionWindow()
+
+	r.OnCongestionEvent(true, before, PacketVector{{Number: 1, Length: DefaultMaxDatagramSize}}, nil)
+
+	if got, want := r.GetCongestionWindow(), before+maxDatagramSize; got != want {
+		t.Fatalf("GetCongestionWindow() after one slow-start ACK = %d, want %d", got, want)
+	}
+}
+
+func TestRenoNotCwndLimitedDoesNotGrowWindow(t *testing.T) {
+	r := newTestRenoSender()
+	before := r.GetCongestionWindow()
+
+	// bytesInFlight well below cwnd: the connection isn't using the window it
+	// already has, so acking more shouldn't grow it further.
+	r.OnCongestionEvent(true, 0, PacketVector{{Number: 1, Length: DefaultMaxDatagramSize}}, nil)
+
+	if got := r.GetCongestionWindow(); got != before {
+		t.Fatalf("GetCongestionWindow() = %d, want unchanged %d when not cwnd-limited", got, before)
+	}
+}
+
+func TestRenoOnPacketLostHalvesWindow(t *testing.T) {
+	r := newTestRenoSender()
+	r.largestSentPacketNumber = 10
+	before := r.GetCongestionWindow()
+
+	r.OnCongestionEvent(true, before, nil, PacketVector{{Number: 5, Length: DefaultMaxDatagramSize}})
+
+	want := protocol.ByteCount(float64(before) * renoBeta)
+	if got := r.GetCongestionWindow(); got != want {
+		t.Fatalf("GetCongestionWindow() after loss = %d, want %d (renoBeta cut of %d)", got, want, before)
+	}
+	if r.slowStartThreshold != want {
+		t.Fatalf("slowStartThreshold after loss = %d, want %d", r.slowStartThreshold, want)
+	}
+}
+
+func TestRenoOnRetransmissionTimeoutResetsToMinWindow(t *testing.T) {
+	r := newTestRenoSender()
+
+	r.OnRetransmissionTimeout(true)
+
+	if got := r.GetCongestionWindow(); got != r.minCongestionWindow {
+		t.Fatalf("GetCongestionWindow() after RTO = %d, want minCongestionWindow %d", got, r.minCongestionWindow)
+	}
+}
+
+func TestRenoRetransmissionDelayIsZeroWithoutRTTSamples(t *testing.T) {
+	r := newTestRenoSender()
+	if got := r.RetransmissionDelay(); got != 0 {
+		t.Fatalf("RetransmissionDelay() with no RTT samples = %v, want 0", got)
+	}
+
+	r.rttStats.UpdateRTT(100*time.Millisecond, 0, time.Now())
+	if got := r.RetransmissionDelay(); got <= 0 {
+		t.Fatalf("RetransmissionDelay() after an RTT sample = %v, want > 0", got)
+	}
+}
@@ -0,0 +1,68 @@
+package congestion
+
+import "time"
+
+const (
+	rttAlpha      float32 = 0.125
+	oneMinusAlpha float32 = 1 - rttAlpha
+	rttBeta       float32 = 0.25
+	oneMinusBeta  float32 = 1 - rttBeta
+)
+
+// RTTStats tracks the smoothed RTT, RTT variance and min RTT for a connection.
+// It is shared between the sentPacketHandler and the congestion controller,
+// so both see the same view of the path's round-trip time.
+type RTTStats struct {
+	minRTT        time.Duration
+	latestRTT     time.Duration
+	smoothedRTT   time.Duration
+	meanDeviation time.Duration
+}
+
+// MinRTT returns the minimum RTT observed over the lifetime of the connection.
+func (r *RTTStats) MinRTT() time.Duration { return r.minRTT }
+
+// LatestRTT returns the most recently measured RTT sample.
+func (r *RTTStats) LatestRTT() time.Duration { return r.latestRTT }
+
+// SmoothedRTT returns the EWMA-smoothed RTT (RFC 6298's SRTT).
+func (r *RTTStats) SmoothedRTT() time.Duration { return r.smoothedRTT }
+
+// MeanDeviation returns the RTT variance estimate (RFC 6298's RTTVAR).
+func (r *RTTStats) MeanDeviation() time.Duration { return r.meanDeviation }
+
+// UpdateRTT updates the RTT estimate using a new sample, following the
+// standard TCP RTT estimator (RFC 6298), with ackDelay subtracted from the
+// sample before it is folded into the smoothed RTT, unless that would bring
+// the sample down to or below the min RTT.
+func (r *RTTStats) UpdateRTT(sendDelta, ackDelay time.Duration, now time.Time) {
+	if sendDelta <= 0 {
+		return
+	}
+
+	if r.minRTT == 0 || sendDelta < r.minRTT {
+		r.minRTT = sendDelta
+	}
+
+	sample := sendDelta
+	if sample > r.minRTT+ackDelay {
+		sample -= ackDelay
+	}
+	r.latestRTT = sample
+
+	if r.smoothedRTT == 0 {
+		r.smoothedRTT = sample
+		r.meanDeviation = sample / 2
+		return
+	}
+
+	r.meanDeviation = time.Duration(oneMinusBeta*float32(r.meanDeviation) + rttBeta*float32(abs(r.smoothedRTT-sample)))
+	r.smoothedRTT = time.Duration(oneMinusAlpha*float32(r.smoothedRTT) + rttAlpha*float32(sample))
+}
+
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}